@@ -0,0 +1,113 @@
+package tracerr
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Annotation is a single layer of context added by Annotate, carrying
+// its own message and the stack frame captured where it was added.
+type Annotation struct {
+	// Message is the text passed to Annotate at this layer.
+	Message string
+	// Frame is the call site where this layer was added.
+	Frame Frame
+}
+
+// annotationError links a message and a frame captured at the call
+// site to the error it wraps, forming one node of an annotation chain.
+type annotationError struct {
+	msg   string
+	frame Frame
+	err   error
+}
+
+// Annotate wraps err with msg and a stack frame captured at the call
+// site, without discarding context already attached to err.
+//
+// Unlike Wrap, which is a no-op once err is already a tracerr.Error,
+// Annotate always records a new layer, so each call site along a chain
+// of returns can add its own note. The returned Error implements
+// Unwrap() error, so errors.Is and errors.As still see through to err.
+func Annotate(err error, msg string, args ...interface{}) Error {
+	if err == nil {
+		return nil
+	}
+	return annotate(err, fmt.Sprintf(msg, args...), 2)
+}
+
+// Wrapf is an alias for Annotate.
+func Wrapf(err error, msg string, args ...interface{}) Error {
+	if err == nil {
+		return nil
+	}
+	return annotate(err, fmt.Sprintf(msg, args...), 2)
+}
+
+func annotate(err error, msg string, skip int) Error {
+	frame := Frame{}
+	pc, path, line, ok := runtime.Caller(skip)
+	if ok {
+		frame = Frame{
+			Func:      runtime.FuncForPC(pc).Name(),
+			Line:      line,
+			Path:      path,
+			Goroutine: currentGoroutineID(),
+		}
+	}
+	return &annotationError{
+		msg:   msg,
+		frame: frame,
+		err:   err,
+	}
+}
+
+// Error returns the message for this layer followed by the error it
+// wraps, in the same style as fmt.Errorf("%s: %w", msg, err).
+func (e *annotationError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+// StackTrace returns the deepest frames available: the stack captured
+// where the root cause was first traced. Use Annotations to see every
+// layer added along the way.
+func (e *annotationError) StackTrace() []Frame {
+	if inner, ok := e.err.(Error); ok {
+		return inner.StackTrace()
+	}
+	return []Frame{e.frame}
+}
+
+// Unwrap returns the error this layer annotates.
+func (e *annotationError) Unwrap() error {
+	return e.err
+}
+
+// Annotations returns every annotation layer wrapped around err,
+// outermost first: the layer added last comes first and the one added
+// first comes last. It returns nil if err carries no annotations.
+func Annotations(err error) []Annotation {
+	var annotations []Annotation
+	for {
+		ae, ok := err.(*annotationError)
+		if !ok {
+			return annotations
+		}
+		annotations = append(annotations, Annotation{Message: ae.msg, Frame: ae.frame})
+		err = ae.err
+	}
+}
+
+// Cause walks Unwrap past every annotation layer and returns the root
+// non-annotated error, so callers can check it against a sentinel
+// without being defeated by intermediate Annotate calls, e.g.
+// errors.Is(tracerr.Cause(err), os.ErrNotExist).
+func Cause(err error) error {
+	for {
+		ae, ok := err.(*annotationError)
+		if !ok {
+			return err
+		}
+		err = ae.err
+	}
+}
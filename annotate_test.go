@@ -0,0 +1,40 @@
+package tracerr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCauseAndAnnotations(t *testing.T) {
+	sentinel := errors.New("not found")
+	err := Annotate(Annotate(Wrapf(sentinel, "loading %s", "config"), "handling request"), "in middleware")
+
+	cause := Cause(err)
+	if cause != sentinel {
+		t.Errorf("Cause returned %v, want the sentinel error", cause)
+	}
+
+	annotations := Annotations(err)
+	wantMessages := []string{"in middleware", "handling request", "loading config"}
+	if len(annotations) != len(wantMessages) {
+		t.Fatalf("got %d annotations, want %d: %+v", len(annotations), len(wantMessages), annotations)
+	}
+	for i, msg := range wantMessages {
+		if annotations[i].Message != msg {
+			t.Errorf("annotation %d = %q, want %q", i, annotations[i].Message, msg)
+		}
+		if annotations[i].Frame.Func == "" {
+			t.Errorf("annotation %d has no captured frame", i)
+		}
+	}
+}
+
+func TestCauseWithoutAnnotations(t *testing.T) {
+	sentinel := errors.New("not found")
+	if Cause(sentinel) != sentinel {
+		t.Error("Cause of a plain error should return that error unchanged")
+	}
+	if Annotations(sentinel) != nil {
+		t.Error("Annotations of a plain error should be nil")
+	}
+}
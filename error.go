@@ -16,10 +16,21 @@ var DefaultCap = 20
 var DefaultPrintStackMaxDepth = 5
 
 // Error is an error with stack trace.
+//
+// BREAKING CHANGE as of Join: this interface no longer declares
+// Unwrap() error, which it did before. Join needs to return a value
+// whose Unwrap() returns []error, and Go doesn't allow a single type
+// to also expose an Unwrap() error method of the same name, so there
+// is no signature under which Error could require both. Code that
+// asserted a tracerr.Error also implements `interface{ Unwrap() error }`
+// will need to perform that assertion itself instead of relying on it
+// being part of Error. Callers that need to walk the chain should use
+// the package-level Unwrap, Is and As functions, which detect
+// whichever Unwrap shape a given error implements, the same way the
+// errors package itself does.
 type Error interface {
 	Error() string
 	StackTrace() []Frame
-	Unwrap() error
 }
 
 type errorData struct {
@@ -77,15 +88,26 @@ func Wrap(err error, skip ...int) Error {
 }
 
 // Unwrap returns the original error.
+//
+// If err was produced by Join, there is no single original error, so
+// Unwrap falls back to the first non-nil one it joined.
 func Unwrap(err error) error {
 	if err == nil {
 		return nil
 	}
-	e, ok := err.(Error)
-	if !ok {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return u.Unwrap()
+	case interface{ Unwrap() []error }:
+		for _, inner := range u.Unwrap() {
+			if inner != nil {
+				return inner
+			}
+		}
+		return nil
+	default:
 		return err
 	}
-	return e.Unwrap()
 }
 
 // Error returns error message.
@@ -112,6 +134,10 @@ type Frame struct {
 	Line int
 	// Path contains a file path.
 	Path string
+	// Goroutine is the id of the goroutine that was running when this
+	// frame was captured, as reported by runtime.Stack. It's 0 if the
+	// id couldn't be parsed.
+	Goroutine int
 }
 
 // StackTrace returns stack trace of an error.
@@ -132,6 +158,7 @@ func (f Frame) String() string {
 func trace(err error, skip int) Error {
 	frames := make([]Frame, 0, DefaultCap)
 	catchMaxCall := DefaultPrintStackMaxDepth
+	goroutine := currentGoroutineID()
 	for {
 		catchMaxCall--
 		pc, path, line, ok := runtime.Caller(skip)
@@ -140,9 +167,10 @@ func trace(err error, skip int) Error {
 		}
 		fn := runtime.FuncForPC(pc)
 		frame := Frame{
-			Func: fn.Name(),
-			Line: line,
-			Path: path,
+			Func:      fn.Name(),
+			Line:      line,
+			Path:      path,
+			Goroutine: goroutine,
 		}
 		frames = append(frames, frame)
 		skip++
@@ -155,306 +183,3 @@ func trace(err error, skip int) Error {
 		frames: frames,
 	}
 }
-
-const  _=`
-package fmt
-
-import (
-	"io/ioutil"
-	"runtime"
-	"strings"
-	"sync"
-)
-
-// DefaultLinesAfter is number of source lines after traced line to display.
-var DefaultLinesAfter = 2
-
-// DefaultLinesBefore is number of source lines before traced line to display.
-var DefaultLinesBefore = 3
-
-var cache = map[string][]string{}
-
-var mutex sync.RWMutex
-
-type Error interface {
-	Error() string
-	StackTrace() []Frame
-	Unwrap() error
-}
-
-type Frame struct {
-	// Func contains a function name.
-	Func string
-	// Line contains a line number.
-	Line int
-	// Path contains a file path.
-	Path string
-}
-
-// Print prints error message with stack trace.
-//func Print(err error) {
-//	fmt.Println(Sprint(err))
-//}
-
-// PrintSource prints error message with stack trace and source fragments.
-//
-// By default 6 lines of source code will be printed,
-// see DefaultLinesAfter and DefaultLinesBefore.
-//
-// Pass a single number to specify a total number of source lines.
-//
-// Pass two numbers to specify exactly how many lines should be shown
-// before and after traced line.
-
-type errorData struct {
-	// err contains original error.
-	err error
-	// frames contains stack trace of an error.
-	frames []Frame
-}
-
-// CustomError creates an error with provided frames.
-func CustomError(err error, frames []Frame) Error {
-	return &errorData{
-		err:    err,
-		frames: frames,
-	}
-}
-
-// Errorf creates new error with stacktrace and formatted message.
-// Formatting works the same way as in fmt.Errorf.
-//func Errorf(message string, args ...interface{}) Error {
-//	return trace(Errorf(message, args...), 2)
-//}
-
-// New creates new error with stacktrace.
-func NewError(message string, a ...interface{}) Error {
-	return trace(Errorf(message, a...), 2)
-}
-
-// Wrap adds stacktrace to existing error.
-
-func WrapError(err error, skip ...int) Error {
-	if err == nil {
-		return nil
-	}
-	e, ok := err.(Error)
-	if ok {
-		return e
-	}
-	if len(skip) > 0 {
-		return trace(err, skip[0])
-	} else {
-		return trace(err, 2)
-	}
-}
-
-var DefaultCap = 20
-
-func trace(err error, skip int) Error {
-	frames := make([]Frame, 0, DefaultCap)
-	for {
-		pc, path, line, ok := runtime.Caller(skip)
-		if !ok {
-			break
-		}
-		fn := runtime.FuncForPC(pc)
-		frame := Frame{
-			Func: fn.Name(),
-			Line: line,
-			Path: path,
-		}
-		frames = append(frames, frame)
-		skip++
-	}
-	return &errorData{
-		err:    err,
-		frames: frames,
-	}
-}
-
-// Unwrap returns the original error.
-func Unwrap(err error) error {
-	if err == nil {
-		return nil
-	}
-	e, ok := err.(Error)
-	if !ok {
-		return err
-	}
-	return e.Unwrap()
-}
-
-// Error returns error message.
-func (e *errorData) Error() string {
-	return e.err.Error()
-}
-
-// StackTrace returns stack trace of an error.
-func (e *errorData) StackTrace() []Frame {
-	return e.frames
-}
-
-// Unwrap returns the original error.
-func (e *errorData) Unwrap() error {
-	return e.err
-}
-
-func PrintSource(err error, nums ...int) {
-	Println(SprintSource(err, nums...))
-}
-
-// PrintSourceColor prints error message with stack trace and source fragments,
-// which are in color.
-// Output rules are the same as in PrintSource.
-func PrintSourceColor(err error, nums ...int) {
-	Println(SprintSourceColor(err, nums...))
-}
-
-// Sprint returns error output by the same rules as Print.
-//func Sprint(err error) string {
-//	return sprint(err, []int{0}, false)
-//}
-
-func GetErrorStack(err error) string {
-	return sprint(err, []int{0}, false)
-}
-
-// SprintSource returns error output by the same rules as PrintSource.
-func SprintSource(err error, nums ...int) string {
-	return sprint(err, nums, false)
-}
-
-// SprintSourceColor returns error output by the same rules as PrintSourceColor.
-func SprintSourceColor(err error, nums ...int) string {
-	return sprint(err, nums, true)
-}
-
-func calcRows(nums []int) (before, after int, withSource bool) {
-	before = DefaultLinesBefore
-	after = DefaultLinesAfter
-	withSource = true
-	if len(nums) > 1 {
-		before = nums[0]
-		after = nums[1]
-		withSource = true
-	} else if len(nums) == 1 {
-		if nums[0] > 0 {
-			// Extra line goes to "before" rather than "after".
-			after = (nums[0] - 1) / 2
-			before = nums[0] - after - 1
-		} else {
-			after = 0
-			before = 0
-			withSource = false
-		}
-	}
-	if before < 0 {
-		before = 0
-	}
-	if after < 0 {
-		after = 0
-	}
-	return before, after, withSource
-}
-
-func readLines(path string) ([]string, error) {
-	mutex.RLock()
-	lines, ok := cache[path]
-	mutex.RUnlock()
-	if ok {
-		return lines, nil
-	}
-
-	b, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, Errorf("tracerr: file %s not found", path)
-	}
-	lines = strings.Split(string(b), "\n")
-	mutex.Lock()
-	defer mutex.Unlock()
-	cache[path] = lines
-	return lines, nil
-}
-
-func sourceRows(rows []string, frame Frame, before, after int, colorized bool) []string {
-	lines, err := readLines(frame.Path)
-	if err != nil {
-		message := err.Error()
-		if colorized {
-			message = message // aurora.Brown(message).String()
-		}
-		return append(rows, message, "")
-	}
-	if len(lines) < frame.Line {
-		message := Sprintf(
-			"tracerr: too few lines, got %d, want %d",
-			len(lines), frame.Line,
-		)
-		if colorized {
-			message = message // aurora.Brown(message).String()
-		}
-		return append(rows, message, "")
-	}
-	current := frame.Line - 1
-	start := current - before
-	end := current + after
-	for i := start; i <= end; i++ {
-		if i < 0 || i >= len(lines) {
-			continue
-		}
-		line := lines[i]
-		var message string
-		// TODO Pad to the same length.
-		if i == frame.Line-1 {
-			message = Sprintf("%d\t%s", i+1, string(line))
-			if colorized {
-				message = message // aurora.Red(message).String()
-			}
-		} else if colorized {
-			message = message // aurora.Sprintf("%d\t%s", aurora.Black(i+1), string(line))
-		} else {
-			message = Sprintf("%d\t%s", i+1, string(line))
-		}
-		rows = append(rows, message)
-	}
-	return append(rows, "")
-}
-
-func sprint(err error, nums []int, colorized bool) string {
-	if err == nil {
-		return ""
-	}
-	e, ok := err.(Error)
-	if !ok {
-		return err.Error()
-	}
-	before, after, withSource := calcRows(nums)
-	frames := e.StackTrace()
-	expectedRows := len(frames) + 1
-	if withSource {
-		expectedRows = (before+after+3)*len(frames) + 2
-	}
-	rows := make([]string, 0, expectedRows)
-	rows = append(rows, e.Error())
-	if withSource {
-		rows = append(rows, "")
-	}
-	for _, frame := range frames {
-		message := frame.String()
-		if colorized {
-			message = message // aurora.Bold(message).String()
-		}
-		rows = append(rows, message)
-		if withSource {
-			rows = sourceRows(rows, frame, before, after, colorized)
-		}
-	}
-	return strings.Join(rows, "\n")
-}
-
-func (f Frame) String() string {
-	return Sprintf("%s:%d %s()", f.Path, f.Line, f.Func)
-}
-
-`
@@ -8,7 +8,8 @@ import (
 
 func main() {
 	if err := nilError(); err != nil {
-		tracerr.PrintSourceColor(err)
+		tracerr.ColorMode = true
+		tracerr.PrintSource(err)
 	} else {
 		fmt.Println("no error")
 	}
@@ -0,0 +1,71 @@
+package tracerr
+
+import "fmt"
+
+// ColorMode controls whether Sprint, SprintSource and the %+v
+// fmt.Formatter verb colorize their output with ANSI escape codes.
+// It is a single package-level switch rather than a parallel set of
+// "Color" functions, so there is only one code path to keep in sync.
+var ColorMode = false
+
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorBrown = "\033[33m"
+	colorBlack = "\033[90m"
+)
+
+func colorize(s, color string, colorized bool) string {
+	if !colorized || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// Format implements fmt.Formatter so that *errorData drops into any
+// fmt-based logger or template without callers having to reach for
+// Sprint/SprintSource explicitly:
+//
+//	%v, %s  the error message
+//	%q      the quoted error message
+//	%+v     the full chain with stack trace and source fragments,
+//	        colorized when ColorMode is set
+//	%#v     a Go-syntax slice of frames, for machine consumption
+func (e *errorData) Format(f fmt.State, verb rune) {
+	formatError(e, f, verb)
+}
+
+// Format implements fmt.Formatter; see (*errorData).Format.
+func (e *annotationError) Format(f fmt.State, verb rune) {
+	formatError(e, f, verb)
+}
+
+func formatError(err Error, f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			fmt.Fprint(f, framesGoString(err.StackTrace()))
+		case f.Flag('+'):
+			fmt.Fprint(f, sprint(err, nil, ColorMode))
+		default:
+			fmt.Fprint(f, err.Error())
+		}
+	case 's':
+		fmt.Fprint(f, err.Error())
+	case 'q':
+		fmt.Fprintf(f, "%q", err.Error())
+	}
+}
+
+func framesGoString(frames []Frame) string {
+	s := "[]tracerr.Frame{"
+	for i, frame := range frames {
+		if i > 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("{Path:%q, Line:%d, Func:%q}", frame.Path, frame.Line, frame.Func)
+	}
+	return s + "}"
+}
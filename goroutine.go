@@ -0,0 +1,112 @@
+package tracerr
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// currentGoroutineID parses the numeric id out of the first line of
+// runtime.Stack, e.g. "goroutine 42 [running]:". The parse happens
+// once per capture (trace, Annotate, Join, ...), never per frame, and
+// is reused for every frame taken in that same call since they all
+// belong to the same goroutine. It returns 0 if the id can't be
+// parsed, which callers treat as "unknown" rather than an error.
+func currentGoroutineID() int {
+	buf := make([]byte, 64)
+	n := runtime.Stack(buf, false)
+	buf = buf[:n]
+	const prefix = "goroutine "
+	if len(buf) < len(prefix) || string(buf[:len(prefix)]) != prefix {
+		return 0
+	}
+	rest := buf[len(prefix):]
+	i := 0
+	for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	id, err := strconv.Atoi(string(rest[:i]))
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// asyncError links an error captured in one goroutine back to the
+// Error whose stack shows where that goroutine was launched from.
+type asyncError struct {
+	err    Error
+	parent Error
+}
+
+// WrapAsync wraps err (capturing a stack trace for it if it isn't
+// already a tracerr.Error) and links it to parent, the Error captured
+// in the goroutine that launched the one err occurred in. Printing the
+// result shows both stacks, so a panic recovered in a worker no longer
+// loses its connection to the handler that dispatched it:
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//		launch := tracerr.New("dispatching request")
+//		go func() {
+//			defer func() {
+//				if r := recover(); r != nil {
+//					err := tracerr.WrapAsync(launch, fmt.Errorf("worker panic: %v", r))
+//					tracerr.PrintSource(err)
+//				}
+//			}()
+//			...
+//		}()
+//	}
+func WrapAsync(parent Error, err error) Error {
+	if err == nil {
+		return nil
+	}
+	e, ok := err.(Error)
+	if !ok {
+		e = trace(err, 2)
+	}
+	return &asyncError{err: e, parent: parent}
+}
+
+// Error returns the wrapped error's message; the goroutine linkage is
+// only visible in Sprint/SprintSource/%+v output and in MarshalJSON.
+func (e *asyncError) Error() string {
+	return e.err.Error()
+}
+
+// StackTrace returns the wrapped error's own stack trace. Use Sprint
+// or SprintSource to see the parent goroutine's stack as well.
+func (e *asyncError) StackTrace() []Frame {
+	return e.err.StackTrace()
+}
+
+// Unwrap returns the wrapped error.
+func (e *asyncError) Unwrap() error {
+	return e.err
+}
+
+// Format implements fmt.Formatter; see (*errorData).Format. %+v prints
+// the goroutine header followed by the parent goroutine's stack, the
+// same way Sprint does.
+func (e *asyncError) Format(f fmt.State, verb rune) {
+	formatError(e, f, verb)
+}
+
+func asyncHeader(e *asyncError) string {
+	frames := e.err.StackTrace()
+	goroutine := 0
+	if len(frames) > 0 {
+		goroutine = frames[0].Goroutine
+	}
+	if e.parent == nil {
+		return fmt.Sprintf("goroutine %d: %s", goroutine, e.err.Error())
+	}
+	parentFrames := e.parent.StackTrace()
+	if len(parentFrames) == 0 {
+		return fmt.Sprintf("goroutine %d: %s", goroutine, e.err.Error())
+	}
+	return fmt.Sprintf(
+		"goroutine %d: %s (caused by goroutine %d which launched it at %s)",
+		goroutine, e.err.Error(), parentFrames[0].Goroutine, parentFrames[0].String(),
+	)
+}
@@ -0,0 +1,16 @@
+package tracerr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatAsyncIncludesParentStack(t *testing.T) {
+	parent := New("dispatching request")
+	err := WrapAsync(parent, fmt.Errorf("worker panic"))
+	out := fmt.Sprintf("%+v", err)
+	if !strings.Contains(out, "dispatching request") {
+		t.Errorf("%%+v on an async error dropped the parent goroutine's trace:\n%s", out)
+	}
+}
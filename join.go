@@ -0,0 +1,145 @@
+package tracerr
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// joinError is the result of Join: a single captured frame plus every
+// non-nil error it was given. It exposes Unwrap() []error rather than
+// Unwrap() error, so errors.Is and errors.As (Go 1.20+) tree-walk into
+// each joined error on their own; see the Error comment for why it
+// can't also expose a single-valued Unwrap.
+type joinError struct {
+	errs  []error
+	frame Frame
+}
+
+// Join captures a stack trace once at the call site and combines errs
+// into a single tracerr.Error, skipping nil entries. It's the tracerr
+// equivalent of Go 1.20's errors.Join, for collecting failures from
+// concurrent goroutines into one traceable value.
+func Join(errs ...error) Error {
+	je := &joinError{}
+	for _, err := range errs {
+		if err != nil {
+			je.errs = append(je.errs, err)
+		}
+	}
+	if len(je.errs) == 0 {
+		return nil
+	}
+	pc, path, line, ok := runtime.Caller(1)
+	if ok {
+		je.frame = Frame{
+			Func:      runtime.FuncForPC(pc).Name(),
+			Line:      line,
+			Path:      path,
+			Goroutine: currentGoroutineID(),
+		}
+	}
+	return je
+}
+
+// Error joins every sub-error's message with a newline, in the style
+// of errors.Join.
+func (e *joinError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// StackTrace returns the single frame captured at the Join call site.
+// Use Unwrap to reach each sub-error's own stack.
+func (e *joinError) StackTrace() []Frame {
+	return []Frame{e.frame}
+}
+
+// Unwrap returns every non-nil error passed to Join.
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+// Format implements fmt.Formatter; see (*errorData).Format. %+v prints
+// the captured frame followed by every joined sub-error, indented,
+// the same way Sprint does.
+func (e *joinError) Format(f fmt.State, verb rune) {
+	formatError(e, f, verb)
+}
+
+// Is reports whether err or anything in its chain, including every
+// branch of a Join and every layer of an Annotate chain, matches
+// target. It's a polyfill of errors.Is for toolchains older than
+// Go 1.20, which is when the standard library learned to walk
+// Unwrap() []error branches.
+func Is(err, target error) bool {
+	if target == nil {
+		return err == target
+	}
+	for err != nil {
+		if err == target {
+			return true
+		}
+		if x, ok := err.(interface{ Is(error) bool }); ok && x.Is(target) {
+			return true
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, inner := range u.Unwrap() {
+				if Is(inner, target) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// As finds the first error in err's chain, including every branch of
+// a Join and every layer of an Annotate chain, that matches target,
+// and if so sets target to that error and returns true. It's a
+// polyfill of errors.As for toolchains older than Go 1.20; see Is.
+func As(err error, target interface{}) bool {
+	if target == nil {
+		panic("tracerr: target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		panic("tracerr: target must be a non-nil pointer")
+	}
+	targetType := typ.Elem()
+	for err != nil {
+		errVal := reflect.ValueOf(err)
+		if errVal.Type().AssignableTo(targetType) {
+			val.Elem().Set(errVal)
+			return true
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok && x.As(target) {
+			return true
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, inner := range u.Unwrap() {
+				if As(inner, target) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
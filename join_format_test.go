@@ -0,0 +1,18 @@
+package tracerr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFormatJoinIncludesSubErrors(t *testing.T) {
+	err := Join(New("job1 failed"), New("job2 failed"))
+	out := fmt.Sprintf("%+v", err)
+	if !strings.Contains(out, "job1 failed") || !strings.Contains(out, "job2 failed") {
+		t.Errorf("%%+v on a joined error dropped a sub-error:\n%s", out)
+	}
+	if !strings.Contains(out, ".go:") {
+		t.Errorf("%%+v on a joined error has no stack frames:\n%s", out)
+	}
+}
@@ -0,0 +1,44 @@
+package tracerr
+
+import (
+	"errors"
+	"testing"
+)
+
+type customErr struct{ msg string }
+
+func (e *customErr) Error() string { return e.msg }
+
+func TestIsAsMixedChain(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	custom := &customErr{msg: "custom"}
+
+	annotated := Annotate(sentinel, "annotated")
+	joined := Join(New("unrelated"), annotated, Annotate(custom, "wrapped"))
+	chain := Annotate(joined, "outer")
+
+	if !Is(chain, sentinel) {
+		t.Error("Is did not find sentinel through Annotate -> Join -> Annotate")
+	}
+	if Is(chain, errors.New("sentinel")) {
+		t.Error("Is matched a different error with the same message")
+	}
+
+	var target *customErr
+	if !As(chain, &target) {
+		t.Fatal("As did not find *customErr through Annotate -> Join -> Annotate")
+	}
+	if target != custom {
+		t.Error("As set target to the wrong value")
+	}
+}
+
+func TestJoinSkipsNilErrs(t *testing.T) {
+	joined := Join(nil, New("only"), nil)
+	if joined == nil {
+		t.Fatal("Join with at least one non-nil error should not return nil")
+	}
+	if je, ok := joined.(*joinError); !ok || len(je.errs) != 1 {
+		t.Fatalf("Join should have skipped nil entries, got %#v", joined)
+	}
+}
@@ -0,0 +1,315 @@
+package tracerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSONIncludeSource controls whether MarshalJSON embeds the source
+// fragment around each frame (DefaultLinesBefore lines before the
+// traced line and DefaultLinesAfter after it, reusing the readLines
+// cache) in its "frames[].source" field. The fragment is read on the
+// side that marshals the error, so the host that receives the JSON
+// needs no source tree to show it. It defaults to off.
+var JSONIncludeSource = false
+
+// frameJSON is the wire representation of a Frame.
+type frameJSON struct {
+	Func      string   `json:"func"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Goroutine int      `json:"goroutine"`
+	Source    []string `json:"source,omitempty"`
+}
+
+// errorJSON is the wire representation of one layer of an error
+// chain. Cause nests a single wrapped error recursively underneath it
+// (Annotate, WrapAsync); Causes holds more than one independent
+// sub-error (Join) instead, since a joined error has no single cause.
+type errorJSON struct {
+	Message   string       `json:"message"`
+	Cause     *errorJSON   `json:"cause,omitempty"`
+	Causes    []*errorJSON `json:"causes,omitempty"`
+	Frames    []frameJSON  `json:"frames,omitempty"`
+	Goroutine int          `json:"goroutine"`
+	Time      string       `json:"time"`
+}
+
+// ToJSON serializes err into the schema produced by MarshalJSON. For
+// errors that don't implement json.Marshaler, it emits just the
+// message, the current goroutine id and the time, with no frames.
+func ToJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+	if m, ok := err.(json.Marshaler); ok {
+		return m.MarshalJSON()
+	}
+	return json.Marshal(&errorJSON{
+		Message:   err.Error(),
+		Goroutine: currentGoroutineID(),
+		Time:      time.Now().Format(time.RFC3339),
+	})
+}
+
+// MarshalJSON renders e as {message, frames, goroutine, time}, so a
+// trace can be shipped to ELK/Loki/Sentry without holding the source
+// tree open on the log host. "cause" is omitted: errorData has no
+// traced cause of its own, only the original error whose message it
+// already carries. Set JSONIncludeSource to embed
+// the source fragment for every frame.
+func (e *errorData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toErrorJSON(e.Error(), e.frames, nil))
+}
+
+// MarshalJSON renders e the same way as (*errorData).MarshalJSON,
+// with this layer's own message and frame.
+func (e *annotationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toErrorJSON(e.msg, []Frame{e.frame}, e.err))
+}
+
+// MarshalJSON renders e as {message, causes, frames, goroutine, time},
+// with every joined sub-error under "causes" rather than collapsing
+// to e's own newline-joined message, so none of them are lost on the
+// way to a log collector or a second FromJSON/ToJSON hop.
+func (e *joinError) MarshalJSON() ([]byte, error) {
+	causes := make([]*errorJSON, len(e.errs))
+	for i, sub := range e.errs {
+		causes[i] = errorToJSON(sub)
+	}
+	ej := toErrorJSON(e.Error(), e.StackTrace(), nil)
+	ej.Causes = causes
+	return json.Marshal(ej)
+}
+
+// MarshalJSON renders e as {message, cause, frames, goroutine, time},
+// with the wrapped error's own frames under "frames" and the parent
+// goroutine's trace nested under "cause", so the cross-goroutine
+// linkage survives being shipped off and rehydrated elsewhere.
+func (e *asyncError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toErrorJSON(e.err.Error(), e.err.StackTrace(), e.parent))
+}
+
+func toErrorJSON(message string, frames []Frame, cause error) *errorJSON {
+	goroutine := currentGoroutineID()
+	if len(frames) > 0 {
+		goroutine = frames[0].Goroutine
+	}
+	ej := &errorJSON{
+		Message:   message,
+		Frames:    framesToJSON(frames),
+		Goroutine: goroutine,
+		Time:      time.Now().Format(time.RFC3339),
+	}
+	if cause != nil {
+		ej.Cause = errorToJSON(cause)
+	}
+	return ej
+}
+
+func framesToJSON(frames []Frame) []frameJSON {
+	fjs := make([]frameJSON, len(frames))
+	for i, frame := range frames {
+		fj := frameJSON{Func: frame.Func, File: frame.Path, Line: frame.Line, Goroutine: frame.Goroutine}
+		if JSONIncludeSource {
+			if lines, err := readLines(frame.Path); err == nil {
+				start := frame.Line - 1 - DefaultLinesBefore
+				if start < 0 {
+					start = 0
+				}
+				end := frame.Line + DefaultLinesAfter
+				if end > len(lines) {
+					end = len(lines)
+				}
+				if start < end {
+					fj.Source = lines[start:end]
+				}
+			}
+		}
+		fjs[i] = fj
+	}
+	return fjs
+}
+
+// errorToJSON converts any error into its wire representation,
+// preferring its own MarshalJSON over just its message so a nested
+// Join/WrapAsync/Annotate cause keeps its frames and structure instead
+// of collapsing to a bare string.
+func errorToJSON(err error) *errorJSON {
+	if m, ok := err.(json.Marshaler); ok {
+		if b, marshalErr := m.MarshalJSON(); marshalErr == nil {
+			var ej errorJSON
+			if json.Unmarshal(b, &ej) == nil {
+				return &ej
+			}
+		}
+	}
+	return &errorJSON{Message: err.Error()}
+}
+
+// FromJSON rehydrates a trace produced by MarshalJSON/ToJSON into a
+// tracerr.Error, so it can be re-printed with PrintSource on a
+// developer workstation after being collected elsewhere.
+//
+// There is deliberately no UnmarshalJSON on errorData or
+// annotationError to pair with their MarshalJSON: unmarshaling never
+// reconstructs the original err value those types wrap, only the
+// message and frames that were serialized, so the result is always a
+// jsonError (or jsonJoinError, for a "causes" layer) rather than the
+// concrete type that produced the bytes. FromJSON makes that one-way
+// trip explicit instead of hiding it behind a same-named method on a
+// type it can't actually rebuild.
+func FromJSON(data []byte) (Error, error) {
+	var ej errorJSON
+	if err := json.Unmarshal(data, &ej); err != nil {
+		return nil, err
+	}
+	return fromErrorJSON(&ej), nil
+}
+
+func fromErrorJSON(ej *errorJSON) Error {
+	frames := framesFromJSON(ej.Frames)
+	if len(ej.Causes) > 0 {
+		errs := make([]error, len(ej.Causes))
+		for i, cj := range ej.Causes {
+			errs[i] = fromErrorJSON(cj)
+		}
+		var frame Frame
+		if len(frames) > 0 {
+			frame = frames[0]
+		}
+		return &jsonJoinError{
+			msg:       ej.Message,
+			frame:     frame,
+			goroutine: ej.Goroutine,
+			time:      ej.Time,
+			errs:      errs,
+		}
+	}
+	var cause error
+	if ej.Cause != nil {
+		cause = fromErrorJSON(ej.Cause)
+	}
+	return &jsonError{
+		msg:       ej.Message,
+		frames:    frames,
+		goroutine: ej.Goroutine,
+		time:      ej.Time,
+		cause:     cause,
+	}
+}
+
+func framesFromJSON(fjs []frameJSON) []Frame {
+	frames := make([]Frame, len(fjs))
+	for i, fj := range fjs {
+		frames[i] = Frame{Func: fj.Func, Path: fj.File, Line: fj.Line, Goroutine: fj.Goroutine}
+	}
+	return frames
+}
+
+// jsonError is a tracerr.Error rehydrated from FromJSON; it has no
+// live stack to capture, only the frames and metadata carried over
+// the wire. It keeps goroutine and time around (rather than just the
+// frames) so that re-serializing it with ToJSON reproduces the same
+// document instead of stamping a fresh goroutine id and timestamp.
+type jsonError struct {
+	msg       string
+	frames    []Frame
+	goroutine int
+	time      string
+	cause     error
+}
+
+// Error returns the message for this layer.
+func (e *jsonError) Error() string {
+	return e.msg
+}
+
+// StackTrace returns the frames carried over the wire for this layer.
+func (e *jsonError) StackTrace() []Frame {
+	return e.frames
+}
+
+// Unwrap returns the cause rehydrated from this layer's "cause" field.
+func (e *jsonError) Unwrap() error {
+	return e.cause
+}
+
+// MarshalJSON renders e the same way as (*errorData).MarshalJSON,
+// walking the cause chain rehydrated from FromJSON instead of a live
+// Unwrap chain, so a trace forwarded through a second hop keeps every
+// frame and layer instead of collapsing to its top message.
+func (e *jsonError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.toErrorJSON())
+}
+
+func (e *jsonError) toErrorJSON() *errorJSON {
+	ej := &errorJSON{
+		Message:   e.msg,
+		Frames:    framesToJSON(e.frames),
+		Goroutine: e.goroutine,
+		Time:      e.time,
+	}
+	if e.cause != nil {
+		ej.Cause = errorToJSON(e.cause)
+	}
+	return ej
+}
+
+// Format implements fmt.Formatter; see (*errorData).Format. %+v walks
+// the rehydrated cause chain the same way collectLayers does, instead
+// of stopping at this layer's own message.
+func (e *jsonError) Format(f fmt.State, verb rune) {
+	formatError(e, f, verb)
+}
+
+// jsonJoinError is a Join result rehydrated from FromJSON: the frame
+// captured at the original Join call site (if any) plus every
+// sub-error, itself rehydrated the same way. It mirrors joinError's
+// shape so Is, As and collectLayers still see a join instead of one
+// flattened message.
+type jsonJoinError struct {
+	msg       string
+	frame     Frame
+	goroutine int
+	time      string
+	errs      []error
+}
+
+// Error returns the newline-joined message carried over the wire.
+func (e *jsonJoinError) Error() string {
+	return e.msg
+}
+
+// StackTrace returns the single frame captured at the original Join
+// call site, the same way joinError.StackTrace does.
+func (e *jsonJoinError) StackTrace() []Frame {
+	return []Frame{e.frame}
+}
+
+// Unwrap returns every sub-error rehydrated from this layer's
+// "causes" field.
+func (e *jsonJoinError) Unwrap() []error {
+	return e.errs
+}
+
+// MarshalJSON renders e the same way as (*joinError).MarshalJSON.
+func (e *jsonJoinError) MarshalJSON() ([]byte, error) {
+	causes := make([]*errorJSON, len(e.errs))
+	for i, err := range e.errs {
+		causes[i] = errorToJSON(err)
+	}
+	return json.Marshal(&errorJSON{
+		Message:   e.msg,
+		Frames:    framesToJSON(e.StackTrace()),
+		Causes:    causes,
+		Goroutine: e.goroutine,
+		Time:      e.time,
+	})
+}
+
+// Format implements fmt.Formatter; see (*errorData).Format.
+func (e *jsonJoinError) Format(f fmt.State, verb rune) {
+	formatError(e, f, verb)
+}
@@ -0,0 +1,136 @@
+package tracerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	err := Annotate(New("boom"), "while handling request")
+
+	b1, marshalErr := ToJSON(err)
+	if marshalErr != nil {
+		t.Fatalf("ToJSON(err) returned error: %v", marshalErr)
+	}
+
+	rehydrated, fromErr := FromJSON(b1)
+	if fromErr != nil {
+		t.Fatalf("FromJSON returned error: %v", fromErr)
+	}
+
+	b2, marshalErr := ToJSON(rehydrated)
+	if marshalErr != nil {
+		t.Fatalf("ToJSON(rehydrated) returned error: %v", marshalErr)
+	}
+
+	if string(b1) != string(b2) {
+		t.Fatalf("round trip lost data:\nfirst:  %s\nsecond: %s", b1, b2)
+	}
+
+	if len(rehydrated.StackTrace()) == 0 {
+		t.Error("rehydrated error lost its own frames")
+	}
+	cause := Unwrap(rehydrated)
+	if cause == nil || len(StackTrace(cause)) == 0 {
+		t.Error("rehydrated error lost its cause's frames")
+	}
+}
+
+func TestFormatJSONIncludesCauseChain(t *testing.T) {
+	err := Annotate(New("boom"), "while handling request")
+	b, marshalErr := ToJSON(err)
+	if marshalErr != nil {
+		t.Fatalf("ToJSON returned error: %v", marshalErr)
+	}
+	rehydrated, fromErr := FromJSON(b)
+	if fromErr != nil {
+		t.Fatalf("FromJSON returned error: %v", fromErr)
+	}
+	out := fmt.Sprintf("%+v", rehydrated)
+	if !strings.Contains(out, "boom") || !strings.Contains(out, "while handling request") {
+		t.Errorf("%%+v on a rehydrated error dropped a layer:\n%s", out)
+	}
+}
+
+func TestJSONRoundTripJoin(t *testing.T) {
+	err := Join(New("job1 failed"), New("job2 failed"))
+
+	b1, marshalErr := ToJSON(err)
+	if marshalErr != nil {
+		t.Fatalf("ToJSON(err) returned error: %v", marshalErr)
+	}
+
+	var ej errorJSON
+	if unmarshalErr := json.Unmarshal(b1, &ej); unmarshalErr != nil {
+		t.Fatalf("could not unmarshal ToJSON output: %v", unmarshalErr)
+	}
+	if len(ej.Causes) != 2 {
+		t.Fatalf("want 2 causes, got %d: %+v", len(ej.Causes), ej.Causes)
+	}
+	if ej.Causes[0].Message != "job1 failed" || ej.Causes[1].Message != "job2 failed" {
+		t.Errorf("causes carry the wrong messages: %+v", ej.Causes)
+	}
+	for i, cause := range ej.Causes {
+		if len(cause.Frames) == 0 {
+			t.Errorf("cause %d lost its frames", i)
+		}
+	}
+
+	rehydrated, fromErr := FromJSON(b1)
+	if fromErr != nil {
+		t.Fatalf("FromJSON returned error: %v", fromErr)
+	}
+	if _, ok := rehydrated.(*jsonJoinError); !ok {
+		t.Fatalf("FromJSON of a Join should return *jsonJoinError, got %T", rehydrated)
+	}
+	if !Is(rehydrated, rehydrated.(*jsonJoinError).errs[0]) {
+		t.Error("Is could not find the first rehydrated sub-error")
+	}
+
+	b2, marshalErr := ToJSON(rehydrated)
+	if marshalErr != nil {
+		t.Fatalf("ToJSON(rehydrated) returned error: %v", marshalErr)
+	}
+	if string(b1) != string(b2) {
+		t.Fatalf("round trip lost data:\nfirst:  %s\nsecond: %s", b1, b2)
+	}
+}
+
+func TestJSONRoundTripAsync(t *testing.T) {
+	parent := New("dispatching request")
+	err := WrapAsync(parent, fmt.Errorf("worker panic"))
+
+	b1, marshalErr := ToJSON(err)
+	if marshalErr != nil {
+		t.Fatalf("ToJSON(err) returned error: %v", marshalErr)
+	}
+
+	var ej errorJSON
+	if unmarshalErr := json.Unmarshal(b1, &ej); unmarshalErr != nil {
+		t.Fatalf("could not unmarshal ToJSON output: %v", unmarshalErr)
+	}
+	if ej.Message != "worker panic" {
+		t.Errorf("want message %q, got %q", "worker panic", ej.Message)
+	}
+	if len(ej.Frames) == 0 {
+		t.Error("async error lost its own frames")
+	}
+	if ej.Cause == nil || ej.Cause.Message != "dispatching request" || len(ej.Cause.Frames) == 0 {
+		t.Errorf("async error lost the parent goroutine's trace: %+v", ej.Cause)
+	}
+
+	rehydrated, fromErr := FromJSON(b1)
+	if fromErr != nil {
+		t.Fatalf("FromJSON returned error: %v", fromErr)
+	}
+
+	b2, marshalErr := ToJSON(rehydrated)
+	if marshalErr != nil {
+		t.Fatalf("ToJSON(rehydrated) returned error: %v", marshalErr)
+	}
+	if string(b1) != string(b2) {
+		t.Fatalf("round trip lost data:\nfirst:  %s\nsecond: %s", b1, b2)
+	}
+}
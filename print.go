@@ -0,0 +1,248 @@
+package tracerr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultLinesAfter is number of source lines after traced line to display.
+var DefaultLinesAfter = 2
+
+// DefaultLinesBefore is number of source lines before traced line to display.
+var DefaultLinesBefore = 3
+
+var sourceCache = map[string][]string{}
+
+var sourceMutex sync.RWMutex
+
+// Print prints error message with stack trace.
+func Print(err error) {
+	fmt.Println(Sprint(err))
+}
+
+// PrintSource prints error message with stack trace and source fragments.
+//
+// By default 6 lines of source code will be printed,
+// see DefaultLinesAfter and DefaultLinesBefore.
+//
+// Pass a single number to specify a total number of source lines.
+//
+// Pass two numbers to specify exactly how many lines should be shown
+// before and after traced line.
+func PrintSource(err error, nums ...int) {
+	fmt.Println(SprintSource(err, nums...))
+}
+
+// Sprint returns error output by the same rules as Print.
+func Sprint(err error) string {
+	return sprint(err, []int{0}, ColorMode)
+}
+
+// SprintSource returns error output by the same rules as PrintSource.
+func SprintSource(err error, nums ...int) string {
+	return sprint(err, nums, ColorMode)
+}
+
+func calcRows(nums []int) (before, after int, withSource bool) {
+	before = DefaultLinesBefore
+	after = DefaultLinesAfter
+	withSource = true
+	if len(nums) > 1 {
+		before = nums[0]
+		after = nums[1]
+		withSource = true
+	} else if len(nums) == 1 {
+		if nums[0] > 0 {
+			// Extra line goes to "before" rather than "after".
+			after = (nums[0] - 1) / 2
+			before = nums[0] - after - 1
+		} else {
+			after = 0
+			before = 0
+			withSource = false
+		}
+	}
+	if before < 0 {
+		before = 0
+	}
+	if after < 0 {
+		after = 0
+	}
+	return before, after, withSource
+}
+
+// readLines returns the source lines for path, serving from
+// sourceCache when possible and falling back to the active
+// SourceProvider (the local filesystem by default; see
+// SetSourceProvider) on a miss.
+func readLines(path string) ([]string, error) {
+	sourceMutex.RLock()
+	lines, ok := sourceCache[path]
+	sourceMutex.RUnlock()
+	if ok {
+		return lines, nil
+	}
+
+	lines, err := activeSourceProvider().ReadLines(path)
+	if err != nil {
+		return nil, err
+	}
+	sourceMutex.Lock()
+	defer sourceMutex.Unlock()
+	sourceCache[path] = lines
+	return lines, nil
+}
+
+func sourceRows(rows []string, frame Frame, before, after int, colorized bool) []string {
+	lines, err := readLines(frame.Path)
+	if err != nil {
+		return append(rows, colorize(err.Error(), colorBrown, colorized), "")
+	}
+	if len(lines) < frame.Line {
+		message := fmt.Sprintf(
+			"tracerr: too few lines, got %d, want %d",
+			len(lines), frame.Line,
+		)
+		return append(rows, colorize(message, colorBrown, colorized), "")
+	}
+	current := frame.Line - 1
+	start := current - before
+	end := current + after
+	for i := start; i <= end; i++ {
+		if i < 0 || i >= len(lines) {
+			continue
+		}
+		// TODO Pad to the same length.
+		message := fmt.Sprintf("%d\t%s", i+1, lines[i])
+		if i == current {
+			message = colorize(message, colorRed, colorized)
+		} else {
+			message = colorize(message, colorBlack, colorized)
+		}
+		rows = append(rows, message)
+	}
+	return append(rows, "")
+}
+
+// sprint renders err as a message followed by its stack trace (and,
+// when withSource is requested, source fragments for every frame).
+// If err carries annotations, each one is rendered as its own layer
+// after the root cause, bottom-up, so the outermost annotation comes
+// last.
+func sprint(err error, nums []int, colorized bool) string {
+	if err == nil {
+		return ""
+	}
+	before, after, withSource := calcRows(nums)
+	layers := collectLayers(err, nums, colorized)
+	rows := make([]string, 0, len(layers)*4)
+	for i, layer := range layers {
+		if i > 0 {
+			rows = append(rows, "")
+		}
+		rows = append(rows, colorize(layer.message, colorBrown, colorized))
+		if withSource {
+			rows = append(rows, "")
+		}
+		for _, frame := range layer.frames {
+			rows = append(rows, colorize(frame.String(), colorBold, colorized))
+			if withSource {
+				rows = sourceRows(rows, frame, before, after, colorized)
+			}
+		}
+		for _, child := range layer.children {
+			for _, line := range strings.Split(child, "\n") {
+				rows = append(rows, "\t"+line)
+			}
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// layer is one printable step of an error chain: either the root cause
+// with its full captured stack, a single annotation with its frame, or
+// a Join header with each joined sub-error rendered as an indented
+// child block beneath it.
+type layer struct {
+	message  string
+	frames   []Frame
+	children []string
+}
+
+// joinLayer builds the layer for a Join header (live or rehydrated):
+// frames holds the single frame captured at the Join call site, and
+// each error in errs is rendered recursively as an indented child.
+func joinLayer(frames []Frame, errs []error, nums []int, colorized bool) layer {
+	children := make([]string, len(errs))
+	for i, sub := range errs {
+		children[i] = sprint(sub, nums, colorized)
+	}
+	return layer{
+		message:  fmt.Sprintf("tracerr: %d joined errors:", len(errs)),
+		frames:   frames,
+		children: children,
+	}
+}
+
+// collectLayers walks err from the root cause outward, turning the
+// chain of annotations into a bottom-up list of printable layers. If
+// the root cause is a Join, each sub-error is rendered recursively and
+// attached as an indented child of the Join's header layer.
+func collectLayers(err error, nums []int, colorized bool) []layer {
+	var annotations []Annotation
+	root := err
+	for {
+		ae, ok := root.(*annotationError)
+		if !ok {
+			break
+		}
+		annotations = append(annotations, Annotation{Message: ae.msg, Frame: ae.frame})
+		root = ae.err
+	}
+	var layers []layer
+	if je, ok := root.(*joinError); ok {
+		layers = append(layers, joinLayer(je.StackTrace(), je.errs, nums, colorized))
+	} else if ae, ok := root.(*asyncError); ok {
+		l := layer{message: asyncHeader(ae), frames: ae.StackTrace()}
+		if ae.parent != nil {
+			l.children = []string{sprint(ae.parent, nums, colorized)}
+		}
+		layers = append(layers, l)
+	} else if jje, ok := root.(*jsonJoinError); ok {
+		layers = append(layers, joinLayer(jje.StackTrace(), jje.errs, nums, colorized))
+	} else if je, ok := root.(*jsonError); ok {
+		// A rehydrated trace carries its own cause chain (each node
+		// already holds just its own message), so walk it the same
+		// way the annotation chain above is walked, rather than
+		// dropping everything past the top layer. If the chain
+		// bottoms out on a rehydrated Join, render that as its own
+		// layer with each sub-error as a child, same as a live Join.
+		var nodes []*jsonError
+		var joined *jsonJoinError
+		for cur := je; ; {
+			nodes = append(nodes, cur)
+			next, ok := cur.cause.(*jsonError)
+			if !ok {
+				joined, _ = cur.cause.(*jsonJoinError)
+				break
+			}
+			cur = next
+		}
+		if joined != nil {
+			layers = append(layers, joinLayer(joined.StackTrace(), joined.errs, nums, colorized))
+		}
+		for i := len(nodes) - 1; i >= 0; i-- {
+			layers = append(layers, layer{message: nodes[i].msg, frames: nodes[i].frames})
+		}
+	} else if e, ok := root.(Error); ok {
+		layers = append(layers, layer{message: e.Error(), frames: e.StackTrace()})
+	} else if root != nil {
+		layers = append(layers, layer{message: root.Error()})
+	}
+	for i := len(annotations) - 1; i >= 0; i-- {
+		a := annotations[i]
+		layers = append(layers, layer{message: a.Message, frames: []Frame{a.Frame}})
+	}
+	return layers
+}
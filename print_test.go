@@ -0,0 +1,31 @@
+package tracerr
+
+import "testing"
+
+func TestCalcRows(t *testing.T) {
+	cases := []struct {
+		name           string
+		nums           []int
+		wantBefore     int
+		wantAfter      int
+		wantWithSource bool
+	}{
+		{"no args uses defaults", nil, DefaultLinesBefore, DefaultLinesAfter, true},
+		{"zero total disables source", []int{0}, 0, 0, false},
+		{"negative total disables source", []int{-5}, 0, 0, false},
+		{"single total of four favors before", []int{4}, 2, 1, true},
+		{"single total of five splits evenly", []int{5}, 2, 2, true},
+		{"single total of one shows only the traced line", []int{1}, 0, 0, true},
+		{"two args set before/after directly", []int{2, 7}, 2, 7, true},
+		{"two args clamp negatives to zero", []int{-1, -2}, 0, 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			before, after, withSource := calcRows(c.nums)
+			if before != c.wantBefore || after != c.wantAfter || withSource != c.wantWithSource {
+				t.Errorf("calcRows(%v) = (%d, %d, %v), want (%d, %d, %v)",
+					c.nums, before, after, withSource, c.wantBefore, c.wantAfter, c.wantWithSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package tracerr
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"embed"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SourceProvider supplies the source lines behind a Frame, so
+// SprintSource/PrintSource can show a snippet around the traced line.
+// The default reads from the local filesystem, which fails in any
+// deployment shipped without its source tree; SetSourceProvider swaps
+// in EmbedSourceProvider, ArchiveSourceProvider, or a custom one.
+type SourceProvider interface {
+	ReadLines(path string) ([]string, error)
+}
+
+var (
+	sourceProviderMu sync.RWMutex
+	sourceProvider   SourceProvider = fsSourceProvider{}
+)
+
+// SetSourceProvider replaces the provider used by readLines/SprintSource
+// for every frame going forward, and drops the line cache so frames
+// already seen are re-read from the new provider. Passing nil restores
+// the default filesystem provider.
+func SetSourceProvider(p SourceProvider) {
+	if p == nil {
+		p = fsSourceProvider{}
+	}
+	sourceProviderMu.Lock()
+	sourceProvider = p
+	sourceProviderMu.Unlock()
+
+	sourceMutex.Lock()
+	sourceCache = map[string][]string{}
+	sourceMutex.Unlock()
+}
+
+func activeSourceProvider() SourceProvider {
+	sourceProviderMu.RLock()
+	defer sourceProviderMu.RUnlock()
+	return sourceProvider
+}
+
+// fsSourceProvider reads source from the local filesystem. It's the
+// default provider.
+type fsSourceProvider struct{}
+
+func (fsSourceProvider) ReadLines(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, Errorf("tracerr: file %s not found", path)
+	}
+	return strings.Split(string(b), "\n"), nil
+}
+
+// EmbedSourceProvider reads source from an embed.FS bound at init, so
+// a binary built with its own source embedded can still show snippets
+// once deployed without the tree on disk. ReadLines looks a frame's
+// path up in FS exactly as captured; frame paths are absolute (or
+// module-qualified under -trimpath) while embed.FS names are relative
+// to the embedding package, so the example maps them with RewritePath:
+//
+//	//go:embed all:myapp
+//	var sourceFS embed.FS
+//
+//	func init() {
+//		tracerr.SetSourceProvider(tracerr.RewritePath(
+//			tracerr.EmbedSourceProvider{FS: sourceFS},
+//			tracerr.StripPathPrefix("/path/to/checkout/"),
+//		))
+//	}
+type EmbedSourceProvider struct {
+	FS embed.FS
+}
+
+// ReadLines implements SourceProvider.
+func (p EmbedSourceProvider) ReadLines(path string) ([]string, error) {
+	b, err := p.FS.ReadFile(path)
+	if err != nil {
+		return nil, Errorf("tracerr: file %s not found in embedded source", path)
+	}
+	return strings.Split(string(b), "\n"), nil
+}
+
+// ArchiveSourceProvider reads source from a zip or tar.gz bundle
+// loaded once at construction, for deployments that ship a source
+// bundle alongside the binary instead of embedding it at compile time.
+type ArchiveSourceProvider struct {
+	files map[string][]string
+}
+
+// NewArchiveSourceProvider opens archivePath (.zip, or .tar.gz/.tgz)
+// and indexes every entry by name so ReadLines can serve it later.
+func NewArchiveSourceProvider(archivePath string) (*ArchiveSourceProvider, error) {
+	if strings.HasSuffix(archivePath, ".zip") {
+		return newZipSourceProvider(archivePath)
+	}
+	return newTarGzSourceProvider(archivePath)
+}
+
+// NewArchiveSourceProviderFromEnv reads the bundle path from envVar,
+// so the archive location can be configured at deploy time rather
+// than baked into the binary.
+func NewArchiveSourceProviderFromEnv(envVar string) (*ArchiveSourceProvider, error) {
+	path := os.Getenv(envVar)
+	if path == "" {
+		return nil, Errorf("tracerr: env var %s is not set", envVar)
+	}
+	return NewArchiveSourceProvider(path)
+}
+
+// ReadLines implements SourceProvider.
+func (p *ArchiveSourceProvider) ReadLines(path string) ([]string, error) {
+	lines, ok := p.files[path]
+	if !ok {
+		return nil, Errorf("tracerr: file %s not found in archive", path)
+	}
+	return lines, nil
+}
+
+func newZipSourceProvider(archivePath string) (*ArchiveSourceProvider, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, Wrap(err)
+	}
+	defer r.Close()
+	files := make(map[string][]string, len(r.File))
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, Wrap(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, Wrap(err)
+		}
+		files[f.Name] = strings.Split(string(b), "\n")
+	}
+	return &ArchiveSourceProvider{files: files}, nil
+}
+
+func newTarGzSourceProvider(archivePath string) (*ArchiveSourceProvider, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, Wrap(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, Wrap(err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	files := map[string][]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, Wrap(err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, Wrap(err)
+		}
+		files[header.Name] = strings.Split(string(b), "\n")
+	}
+	return &ArchiveSourceProvider{files: files}, nil
+}
+
+// PathRewriter maps a path captured in a Frame to whatever key a
+// SourceProvider actually has source under, e.g. stripping a Bazel
+// sandbox prefix like "/proc/self/cwd/" or "bazel-out/...".
+type PathRewriter func(path string) string
+
+// StripPathPrefix returns a PathRewriter that removes prefix from the
+// start of any path it's given.
+func StripPathPrefix(prefix string) PathRewriter {
+	return func(path string) string {
+		return strings.TrimPrefix(path, prefix)
+	}
+}
+
+// RewritePath wraps provider so every path is passed through rewrite
+// before being looked up, letting remote-build paths be mapped back to
+// whatever tree provider actually has.
+func RewritePath(provider SourceProvider, rewrite PathRewriter) SourceProvider {
+	return &rewritingSourceProvider{provider: provider, rewrite: rewrite}
+}
+
+type rewritingSourceProvider struct {
+	provider SourceProvider
+	rewrite  PathRewriter
+}
+
+func (p *rewritingSourceProvider) ReadLines(path string) ([]string, error) {
+	return p.provider.ReadLines(p.rewrite(path))
+}
@@ -0,0 +1,113 @@
+package tracerr
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeZipArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zw.Create(%q): %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("writing %q: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close(): %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "source.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	return path
+}
+
+func writeTarGzArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("tw.WriteHeader(%q): %v", name, err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("writing %q: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tw.Close(): %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gw.Close(): %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "source.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestNewArchiveSourceProviderZip(t *testing.T) {
+	path := writeZipArchive(t, map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+	p, err := NewArchiveSourceProvider(path)
+	if err != nil {
+		t.Fatalf("NewArchiveSourceProvider: %v", err)
+	}
+	lines, err := p.ReadLines("main.go")
+	if err != nil {
+		t.Fatalf("ReadLines: %v", err)
+	}
+	want := []string{"package main", "", "func main() {}", ""}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %v", len(lines), len(want), lines)
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i, lines[i], line)
+		}
+	}
+	if _, err := p.ReadLines("missing.go"); err == nil {
+		t.Error("ReadLines of a missing file should return an error")
+	}
+}
+
+func TestNewArchiveSourceProviderTarGz(t *testing.T) {
+	path := writeTarGzArchive(t, map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+	p, err := NewArchiveSourceProvider(path)
+	if err != nil {
+		t.Fatalf("NewArchiveSourceProvider: %v", err)
+	}
+	lines, err := p.ReadLines("main.go")
+	if err != nil {
+		t.Fatalf("ReadLines: %v", err)
+	}
+	want := []string{"package main", "", "func main() {}", ""}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %v", len(lines), len(want), lines)
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i, lines[i], line)
+		}
+	}
+	if _, err := p.ReadLines("missing.go"); err == nil {
+		t.Error("ReadLines of a missing file should return an error")
+	}
+}